@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/supabase/cli/internal/migration/lint"
+	"github.com/supabase/cli/internal/migration/test"
+	"github.com/supabase/cli/internal/utils"
+)
+
+var migrationCmd = &cobra.Command{
+	GroupID: groupManagementAPI,
+	Use:     "migration",
+	Short:   "Manage database migration scripts",
+}
+
+var (
+	fromRef    string
+	toRef      string
+	diffOutput string
+
+	migrationTestCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Diff the end-state schema produced by migrations between two git revisions",
+		Long: `Checks out supabase/migrations as of --from and --to, applies each set against a
+disposable shadow database, and reports any drift between the two resulting schemas. This
+catches cases where squashing, reordering, or editing migrations changes the mainline
+history's end-state, which AssertRemoteInSync otherwise assumes can never happen.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			return test.Run(ctx, fromRef, toRef, diffOutput, afero.NewOsFs())
+		},
+	}
+
+	migrationLintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Statically lint local migrations against a pluggable rule set",
+		Long: `Checks every file under supabase/migrations against built-in rules covering
+unguarded destructive statements, NOT NULL columns added without a default, non-concurrent
+indexes on large tables, identifier length limits, and edits to reserved internal schemas.
+Individual rules can be disabled via [migrations.lint] in config.toml. Exits non-zero if any
+enabled rule reports an error-severity finding, so it can gate CI.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			fsys := afero.NewOsFs()
+			conn := dialLinkedDatabase(ctx, fsys)
+			if conn != nil {
+				defer conn.Close(context.Background())
+			}
+			return lint.Run(ctx, conn, fsys)
+		},
+	}
+)
+
+// dialLinkedDatabase best-effort connects to the linked remote so
+// row-count-dependent lint rules can run; rules that need it skip their
+// check and report a warning when no connection is available.
+func dialLinkedDatabase(ctx context.Context, fsys afero.Fs) *pgx.Conn {
+	projectRef, err := utils.LoadProjectRef(fsys)
+	if err != nil {
+		return nil
+	}
+	conn, err := utils.ConnectRemotePostgres(ctx, "postgres", "", "postgres", utils.GetSupabaseDbHost(projectRef))
+	if err != nil {
+		return nil
+	}
+	return conn
+}
+
+func init() {
+	migrationTestFlags := migrationTestCmd.Flags()
+	migrationTestFlags.StringVar(&fromRef, "from", "HEAD", "Git ref to diff from.")
+	migrationTestFlags.StringVar(&toRef, "to", "main", "Git ref to diff to.")
+	migrationTestFlags.StringVarP(&diffOutput, "file", "f", "", "Path to write the diff SQL to, instead of stdout.")
+	migrationCmd.AddCommand(migrationTestCmd)
+	migrationCmd.AddCommand(migrationLintCmd)
+	rootCmd.AddCommand(migrationCmd)
+}
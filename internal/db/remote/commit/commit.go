@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/supabase/cli/internal/db/diff"
 	"github.com/supabase/cli/internal/db/dump"
+	"github.com/supabase/cli/internal/migration/hooks"
 	"github.com/supabase/cli/internal/migration/list"
 	"github.com/supabase/cli/internal/migration/repair"
 	"github.com/supabase/cli/internal/utils"
@@ -67,10 +68,33 @@ func run(p utils.Program, ctx context.Context, schema []string, username, passwo
 	if err := fetchRemote(p, ctx, schema, timestamp, username, password, database, host, fsys); err != nil {
 		return err
 	}
+	filename := timestamp + "_remote_commit.sql"
+	migrationPath := filepath.Join(utils.MigrationsDir, filename)
 
-	// 3. Insert a row to `schema_migrations`
-	_, err = conn.Exec(ctx, repair.INSERT_MIGRATION_VERSION, timestamp)
-	return err
+	// 3. Insert a row to `schema_migrations`, then run any `after_up` hooks
+	// in the same transaction so a failing hook rolls the commit back too.
+	// If either step fails, remove the migration file written by fetchRemote
+	// so we don't leave a migration on disk with no matching remote row.
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		_ = fsys.Remove(migrationPath)
+		return err
+	}
+	if _, err := tx.Exec(ctx, repair.INSERT_MIGRATION_VERSION, timestamp); err != nil {
+		_ = tx.Rollback(context.Background())
+		_ = fsys.Remove(migrationPath)
+		return err
+	}
+	if err := hooks.RunAfterUp(ctx, tx, filename, fsys); err != nil {
+		_ = tx.Rollback(context.Background())
+		_ = fsys.Remove(migrationPath)
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		_ = fsys.Remove(migrationPath)
+		return err
+	}
+	return nil
 }
 
 func fetchRemote(p utils.Program, ctx context.Context, schema []string, timestamp, username, password, database, host string, fsys afero.Fs) error {
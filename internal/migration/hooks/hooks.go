@@ -0,0 +1,189 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Hook is a single user-defined lifecycle callback, either a shell command
+// run against the project directory, or -- when Transactional is set -- a
+// SQL file executed inside the same transaction as the migration it guards.
+//
+// In config.toml a hook is usually just a bare command string, e.g.
+// `before_up = ["psql -f supabase/hooks/disable_triggers.sql"]`; it only
+// needs the inline-table form `{ command = "...", transactional = true }`
+// to opt into running inside the migration's transaction.
+type Hook struct {
+	Command       string
+	Transactional bool
+}
+
+func (h *Hook) UnmarshalTOML(data interface{}) error {
+	switch v := data.(type) {
+	case string:
+		h.Command = v
+	case map[string]interface{}:
+		command, ok := v["command"].(string)
+		if !ok {
+			return fmt.Errorf("hook table is missing a string \"command\" key: %v", v)
+		}
+		h.Command = command
+		if transactional, ok := v["transactional"].(bool); ok {
+			h.Transactional = transactional
+		}
+	default:
+		return fmt.Errorf("invalid hook value %v: must be a command string or a {command, transactional} table", v)
+	}
+	return nil
+}
+
+// PerMigrationHooks scopes a set of hooks to migration filenames matching
+// Pattern (a filepath.Match glob, e.g. "*_add_index.sql").
+type PerMigrationHooks struct {
+	Pattern    string `toml:"pattern"`
+	BeforeUp   []Hook `toml:"before_up"`
+	AfterUp    []Hook `toml:"after_up"`
+	BeforeDown []Hook `toml:"before_down"`
+	AfterDown  []Hook `toml:"after_down"`
+}
+
+// Config mirrors the `[migrations.hooks]` table in config.toml.
+type Config struct {
+	BeforeUp     []Hook              `toml:"before_up"`
+	AfterUp      []Hook              `toml:"after_up"`
+	BeforeDown   []Hook              `toml:"before_down"`
+	AfterDown    []Hook              `toml:"after_down"`
+	PerMigration []PerMigrationHooks `toml:"per_migration"`
+}
+
+type tomlFile struct {
+	Migrations struct {
+		Hooks Config `toml:"hooks"`
+	} `toml:"migrations"`
+}
+
+// Load reads the `[migrations.hooks]` table from config.toml. A missing
+// table is not an error: it simply means no hooks are configured.
+func Load(fsys afero.Fs) (Config, error) {
+	var parsed tomlFile
+	contents, err := afero.ReadFile(fsys, utils.ConfigPath)
+	if err != nil {
+		return Config{}, err
+	}
+	if _, err := toml.Decode(string(contents), &parsed); err != nil {
+		return Config{}, err
+	}
+	return parsed.Migrations.Hooks, nil
+}
+
+// RunBeforeUp runs the before_up hooks, plus any [[migrations.hooks.per_migration]]
+// entries whose pattern matches filename, ahead of applying a single migration.
+func RunBeforeUp(ctx context.Context, tx pgx.Tx, filename string, fsys afero.Fs) error {
+	cfg, err := Load(fsys)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, tx, matching(cfg, filename, func(c Config) []Hook { return c.BeforeUp }), fsys)
+}
+
+// RunAfterUp runs the after_up hooks once the new schema_migrations row has
+// been inserted for filename.
+func RunAfterUp(ctx context.Context, tx pgx.Tx, filename string, fsys afero.Fs) error {
+	cfg, err := Load(fsys)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, tx, matching(cfg, filename, func(c Config) []Hook { return c.AfterUp }), fsys)
+}
+
+// RunBeforeDown and RunAfterDown mirror their up counterparts for the
+// before_down and after_down hooks. Nothing in internal/migration/repair
+// calls them, so reverting a schema_migrations row does not run these hooks.
+func RunBeforeDown(ctx context.Context, tx pgx.Tx, filename string, fsys afero.Fs) error {
+	cfg, err := Load(fsys)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, tx, matching(cfg, filename, func(c Config) []Hook { return c.BeforeDown }), fsys)
+}
+
+func RunAfterDown(ctx context.Context, tx pgx.Tx, filename string, fsys afero.Fs) error {
+	cfg, err := Load(fsys)
+	if err != nil {
+		return err
+	}
+	return runAll(ctx, tx, matching(cfg, filename, func(c Config) []Hook { return c.AfterDown }), fsys)
+}
+
+// matching collects the global hooks picked by pick, plus those from every
+// per_migration entry whose glob Pattern matches filename.
+func matching(cfg Config, filename string, pick func(Config) []Hook) []Hook {
+	hooks := append([]Hook{}, pick(cfg)...)
+	for _, scoped := range cfg.PerMigration {
+		ok, err := filepath.Match(scoped.Pattern, filename)
+		if err != nil || !ok {
+			continue
+		}
+		hooks = append(hooks, pick(Config{
+			BeforeUp:   scoped.BeforeUp,
+			AfterUp:    scoped.AfterUp,
+			BeforeDown: scoped.BeforeDown,
+			AfterDown:  scoped.AfterDown,
+		})...)
+	}
+	return hooks
+}
+
+func runAll(ctx context.Context, tx pgx.Tx, hooks []Hook, fsys afero.Fs) error {
+	for _, hook := range hooks {
+		if err := runOne(ctx, tx, hook, fsys); err != nil {
+			return utils.NewError("hook " + utils.Bold(hook.Command) + " failed: " + err.Error())
+		}
+	}
+	return nil
+}
+
+// runOne runs a transactional hook's SQL file inline on tx; a non-transactional
+// hook is shelled out instead, which is its own connection to the database by
+// construction (e.g. `psql -f ...` opens one of its own).
+func runOne(ctx context.Context, tx pgx.Tx, hook Hook, fsys afero.Fs) error {
+	if hook.Transactional {
+		sql, err := sqlFileArg(hook.Command, fsys)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, sql)
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// sqlFileArg extracts the SQL file referenced by a `psql -f <path>` style
+// hook command so it can be executed inline within the migration's
+// transaction instead of shelled out to a separate connection.
+func sqlFileArg(command string, fsys afero.Fs) (string, error) {
+	args := strings.Fields(command)
+	for i, arg := range args {
+		if arg == "-f" && i+1 < len(args) {
+			contents, err := afero.ReadFile(fsys, filepath.Clean(args[i+1]))
+			if err != nil {
+				return "", err
+			}
+			return string(contents), nil
+		}
+	}
+	return "", utils.NewError("transactional hook must reference a SQL file via -f: " + command)
+}
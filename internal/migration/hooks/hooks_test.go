@@ -0,0 +1,80 @@
+package hooks
+
+import (
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func decodeConfig(t *testing.T, body string) Config {
+	t.Helper()
+	var cfg Config
+	if _, err := toml.Decode(body, &cfg); err != nil {
+		t.Fatalf("toml.Decode() error = %v", err)
+	}
+	return cfg
+}
+
+func TestHookUnmarshalTOML(t *testing.T) {
+	t.Run("bare command string", func(t *testing.T) {
+		cfg := decodeConfig(t, `before_up = ["psql -f supabase/hooks/disable_triggers.sql"]`)
+		if len(cfg.BeforeUp) != 1 {
+			t.Fatalf("BeforeUp = %+v, want 1 hook", cfg.BeforeUp)
+		}
+		if got := cfg.BeforeUp[0]; got.Command != "psql -f supabase/hooks/disable_triggers.sql" || got.Transactional {
+			t.Errorf("BeforeUp[0] = %+v, want non-transactional command", got)
+		}
+	})
+
+	t.Run("inline table opts into transactional", func(t *testing.T) {
+		cfg := decodeConfig(t, `after_up = [{ command = "select refresh_views();", transactional = true }]`)
+		if len(cfg.AfterUp) != 1 {
+			t.Fatalf("AfterUp = %+v, want 1 hook", cfg.AfterUp)
+		}
+		if got := cfg.AfterUp[0]; got.Command != "select refresh_views();" || !got.Transactional {
+			t.Errorf("AfterUp[0] = %+v, want a transactional hook", got)
+		}
+	})
+
+	t.Run("table missing command errors", func(t *testing.T) {
+		var cfg Config
+		_, err := toml.Decode(`before_down = [{ transactional = true }]`, &cfg)
+		if err == nil {
+			t.Fatal("toml.Decode() error = nil, want an error for a hook table missing \"command\"")
+		}
+	})
+}
+
+func TestMatching(t *testing.T) {
+	cfg := Config{
+		AfterUp: []Hook{{Command: "global"}},
+		PerMigration: []PerMigrationHooks{
+			{Pattern: "*_add_index.sql", AfterUp: []Hook{{Command: "scoped-index"}}},
+			{Pattern: "*_seed.sql", AfterUp: []Hook{{Command: "scoped-seed"}}},
+		},
+	}
+	pick := func(c Config) []Hook { return c.AfterUp }
+
+	cases := []struct {
+		filename string
+		want     []string
+	}{
+		{"20230101000000_add_index.sql", []string{"global", "scoped-index"}},
+		{"20230101000000_seed.sql", []string{"global", "scoped-seed"}},
+		{"20230101000000_unrelated.sql", []string{"global"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.filename, func(t *testing.T) {
+			hooks := matching(cfg, tc.filename, pick)
+			if len(hooks) != len(tc.want) {
+				t.Fatalf("matching(%q) = %+v, want %d hook(s)", tc.filename, hooks, len(tc.want))
+			}
+			for i, want := range tc.want {
+				if hooks[i].Command != want {
+					t.Errorf("matching(%q)[%d] = %q, want %q", tc.filename, i, hooks[i].Command, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Config mirrors the `[migrations.lint]` table in config.toml.
+type Config struct {
+	Disabled     []string `toml:"disabled"`
+	RowThreshold int64    `toml:"row_threshold"`
+}
+
+type tomlFile struct {
+	Migrations struct {
+		Lint Config `toml:"lint"`
+	} `toml:"migrations"`
+}
+
+// defaultRowThreshold is used by no-concurrent-index when config.toml does
+// not set `row_threshold` explicitly.
+const defaultRowThreshold = 100_000
+
+func loadConfig(fsys afero.Fs) (Config, error) {
+	var parsed tomlFile
+	parsed.Migrations.Lint.RowThreshold = defaultRowThreshold
+	contents, err := afero.ReadFile(fsys, utils.ConfigPath)
+	if err != nil {
+		return Config{}, err
+	}
+	if _, err := toml.Decode(string(contents), &parsed); err != nil {
+		return Config{}, err
+	}
+	return parsed.Migrations.Lint, nil
+}
+
+// Run lints every migration under utils.MigrationsDir and returns a
+// non-nil error if any enabled rule reports an error-severity finding.
+// If conn is non-nil, it is used to resolve row counts for rules that rely
+// on the linked remote; otherwise those rules skip their check and report a
+// warning-level finding instead.
+func Run(ctx context.Context, conn *pgx.Conn, fsys afero.Fs) error {
+	cfg, err := loadConfig(fsys)
+	if err != nil {
+		return err
+	}
+	rules := enabledRules(cfg)
+
+	files, err := afero.ReadDir(fsys, utils.MigrationsDir)
+	if err != nil {
+		return err
+	}
+
+	var findings []Finding
+	for _, file := range files {
+		if !utils.MigrateFilePattern.MatchString(file.Name()) {
+			continue
+		}
+		path := utils.MigrationsDir + "/" + file.Name()
+		contents, err := afero.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		found, err := lintFile(ctx, rules, file.Name(), string(contents), cfg, conn)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, found...)
+	}
+
+	report(findings)
+
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return fmt.Errorf("migration lint found %d error-level finding(s)", countErrors(findings))
+		}
+	}
+	return nil
+}
+
+func enabledRules(cfg Config) []Rule {
+	disabled := make(map[string]bool, len(cfg.Disabled))
+	for _, name := range cfg.Disabled {
+		disabled[name] = true
+	}
+
+	var enabled []Rule
+	for _, rule := range Rules {
+		if !disabled[rule.Name()] {
+			enabled = append(enabled, rule)
+		}
+	}
+	return enabled
+}
+
+func lintFile(ctx context.Context, rules []Rule, filename, contents string, cfg Config, conn *pgx.Conn) ([]Finding, error) {
+	var findings []Finding
+	for _, stmt := range splitStatements(contents) {
+		if strings.TrimSpace(stmt.text) == "" {
+			continue
+		}
+		env := &Env{
+			File:      filename,
+			Line:      stmt.line,
+			Statement: stripComments(stmt.text),
+			Threshold: cfg.RowThreshold,
+			RowCount:  rowCountFunc(conn),
+		}
+		for _, rule := range rules {
+			findings = append(findings, rule.Check(ctx, env)...)
+		}
+	}
+	return findings, nil
+}
+
+func rowCountFunc(conn *pgx.Conn) func(context.Context, string) (int64, error) {
+	if conn == nil {
+		return nil
+	}
+	return func(ctx context.Context, table string) (int64, error) {
+		var count int64
+		err := conn.QueryRow(ctx, "SELECT reltuples::bigint FROM pg_class WHERE oid = $1::regclass", table).Scan(&count)
+		return count, err
+	}
+}
+
+func report(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	for _, finding := range findings {
+		tag := utils.Yellow("warning")
+		if finding.Severity == SeverityError {
+			tag = utils.Red("error")
+		}
+		fmt.Printf("%s:%d: %s: %s [%s]\n", finding.File, finding.Line, tag, finding.Message, finding.Rule)
+	}
+}
+
+func countErrors(findings []Finding) int {
+	count := 0
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+var lineCommentPattern = regexp.MustCompile(`--[^\n]*`)
+var blockCommentPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+// stripComments removes SQL line and block comments so a rule's pattern
+// isn't defeated by e.g. a `-- why we're doing this` line immediately
+// ahead of the statement it's explaining.
+func stripComments(stmt string) string {
+	stmt = blockCommentPattern.ReplaceAllString(stmt, "")
+	stmt = lineCommentPattern.ReplaceAllString(stmt, "")
+	return stmt
+}
+
+type statement struct {
+	text string
+	line int
+}
+
+// splitStatements is a lightweight tokenizer, not a real SQL parser: it
+// splits on statement-terminating semicolons while treating `$$ ... $$`
+// dollar-quoted bodies (used by CREATE FUNCTION) as opaque so they aren't
+// split on semicolons they contain.
+func splitStatements(contents string) []statement {
+	var statements []statement
+	var current strings.Builder
+	line := 1
+	startLine := 1
+	inDollarQuote := false
+
+	lines := strings.Split(contents, "\n")
+	for _, rawLine := range lines {
+		if current.Len() == 0 {
+			startLine = line
+		}
+		if strings.Count(rawLine, "$$")%2 == 1 {
+			inDollarQuote = !inDollarQuote
+		}
+		current.WriteString(rawLine)
+		current.WriteByte('\n')
+
+		if !inDollarQuote && strings.HasSuffix(strings.TrimSpace(rawLine), ";") {
+			statements = append(statements, statement{text: current.String(), line: startLine})
+			current.Reset()
+		}
+		line++
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		statements = append(statements, statement{text: current.String(), line: startLine})
+	}
+	return statements
+}
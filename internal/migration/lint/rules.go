@@ -0,0 +1,173 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Severity classifies how a Finding should affect the command's exit code.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single rule violation, pinned to the statement that
+// triggered it so it can be reported as `file:line: message`.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	File     string
+	Line     int
+	Message  string
+}
+
+// Rule statically checks one migration statement at a time. Rules never see
+// more than a single statement plus the ambient Env, keeping them cheap
+// enough to run against every statement of every migration on each lint.
+type Rule interface {
+	Name() string
+	DefaultSeverity() Severity
+	Check(ctx context.Context, env *Env) []Finding
+}
+
+// Env is the context a Rule is evaluated against: the statement under
+// inspection, the file and line it came from, and an optional live
+// connection to the linked remote for rules that need table statistics.
+type Env struct {
+	File      string
+	Line      int
+	Statement string
+	RowCount  func(ctx context.Context, table string) (int64, error)
+	Threshold int64
+}
+
+// Rules is the built-in, pluggable rule set. Individual rules are toggled
+// off via `disabled` in config.toml; see loadConfig.
+var Rules = []Rule{
+	noDestructiveWithoutGuard{},
+	noNotNullWithoutDefault{},
+	noConcurrentIndex{},
+	identifierLengthLimit{},
+	noInternalSchemaEdits{},
+}
+
+var destructivePattern = regexp.MustCompile(`(?is)^\s*(DROP\s+(TABLE|COLUMN|SCHEMA|INDEX|VIEW|FUNCTION|TYPE)|ALTER\s+TABLE\s+\S+\s+DROP\s+COLUMN)\b`)
+var ifExistsPattern = regexp.MustCompile(`(?is)IF\s+EXISTS`)
+
+type noDestructiveWithoutGuard struct{}
+
+func (noDestructiveWithoutGuard) Name() string              { return "no-destructive-without-guard" }
+func (noDestructiveWithoutGuard) DefaultSeverity() Severity { return SeverityError }
+func (r noDestructiveWithoutGuard) Check(_ context.Context, env *Env) []Finding {
+	if !destructivePattern.MatchString(env.Statement) || ifExistsPattern.MatchString(env.Statement) {
+		return nil
+	}
+	return []Finding{env.finding(r.Name(), r.DefaultSeverity(), "destructive statement is missing an IF EXISTS guard")}
+}
+
+var addColumnNotNullPattern = regexp.MustCompile(`(?is)ALTER\s+TABLE\s+(\S+)\s+ADD\s+COLUMN\s+\S+\s+\S+.*NOT\s+NULL`)
+var defaultPattern = regexp.MustCompile(`(?is)DEFAULT\b`)
+
+type noNotNullWithoutDefault struct{}
+
+func (noNotNullWithoutDefault) Name() string              { return "no-not-null-without-default" }
+func (noNotNullWithoutDefault) DefaultSeverity() Severity { return SeverityError }
+func (r noNotNullWithoutDefault) Check(ctx context.Context, env *Env) []Finding {
+	matches := addColumnNotNullPattern.FindStringSubmatch(env.Statement)
+	if matches == nil || defaultPattern.MatchString(env.Statement) {
+		return nil
+	}
+	rows, err := rowCount(ctx, env, matches[1])
+	if err != nil {
+		return []Finding{env.finding(r.Name(), SeverityWarning, fmt.Sprintf(
+			"cannot verify whether %s is empty without a linked remote; skipping the ADD COLUMN ... NOT NULL check", matches[1],
+		))}
+	}
+	if rows == 0 {
+		return nil
+	}
+	return []Finding{env.finding(r.Name(), r.DefaultSeverity(), "ADD COLUMN ... NOT NULL on a non-empty table needs a DEFAULT")}
+}
+
+var createIndexPattern = regexp.MustCompile(`(?is)CREATE\s+(UNIQUE\s+)?INDEX\s+(?:CONCURRENTLY\s+)?\S+\s+ON\s+(\S+)`)
+var concurrentlyPattern = regexp.MustCompile(`(?is)CONCURRENTLY`)
+
+type noConcurrentIndex struct{}
+
+func (noConcurrentIndex) Name() string              { return "no-concurrent-index" }
+func (noConcurrentIndex) DefaultSeverity() Severity { return SeverityWarning }
+func (r noConcurrentIndex) Check(ctx context.Context, env *Env) []Finding {
+	matches := createIndexPattern.FindStringSubmatch(env.Statement)
+	if matches == nil || concurrentlyPattern.MatchString(env.Statement) {
+		return nil
+	}
+	rows, err := rowCount(ctx, env, matches[2])
+	if err != nil {
+		return []Finding{env.finding(r.Name(), SeverityWarning, fmt.Sprintf(
+			"cannot verify row count for %s without a linked remote; skipping the non-concurrent index check", matches[2],
+		))}
+	}
+	if rows < env.Threshold {
+		return nil
+	}
+	return []Finding{env.finding(r.Name(), r.DefaultSeverity(), fmt.Sprintf(
+		"creating an index on %s (%d rows) without CONCURRENTLY will lock writes", matches[2], rows,
+	))}
+}
+
+var identifierPattern = regexp.MustCompile(`(?is)(?:CREATE|ALTER)\s+(?:TABLE|INDEX|VIEW|TYPE|FUNCTION)\s+(?:IF\s+NOT\s+EXISTS\s+)?([A-Za-z0-9_."]+)`)
+
+// maxIdentifierLength is Postgres' identifier cap; see
+// https://www.postgresql.org/docs/current/sql-syntax-lexical.html#SQL-SYNTAX-IDENTIFIERS.
+const maxIdentifierLength = 63
+
+type identifierLengthLimit struct{}
+
+func (identifierLengthLimit) Name() string              { return "identifier-length-limit" }
+func (identifierLengthLimit) DefaultSeverity() Severity { return SeverityError }
+func (r identifierLengthLimit) Check(_ context.Context, env *Env) []Finding {
+	matches := identifierPattern.FindStringSubmatch(env.Statement)
+	if matches == nil {
+		return nil
+	}
+	name := strings.Trim(matches[1], `"`)
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if len(name) <= maxIdentifierLength {
+		return nil
+	}
+	return []Finding{env.finding(r.Name(), r.DefaultSeverity(), fmt.Sprintf(
+		"identifier %q is %d characters, over Postgres' %d-character limit", name, len(name), maxIdentifierLength,
+	))}
+}
+
+type noInternalSchemaEdits struct{}
+
+func (noInternalSchemaEdits) Name() string              { return "no-internal-schema-edits" }
+func (noInternalSchemaEdits) DefaultSeverity() Severity { return SeverityError }
+func (r noInternalSchemaEdits) Check(_ context.Context, env *Env) []Finding {
+	for _, schema := range utils.InternalSchemas {
+		if strings.Contains(strings.ToLower(env.Statement), schema+".") {
+			return []Finding{env.finding(r.Name(), r.DefaultSeverity(), "migrations should not modify the reserved "+schema+" schema")}
+		}
+	}
+	return nil
+}
+
+func (env *Env) finding(rule string, severity Severity, message string) Finding {
+	return Finding{Rule: rule, Severity: severity, File: env.File, Line: env.Line, Message: message}
+}
+
+func rowCount(ctx context.Context, env *Env, table string) (int64, error) {
+	if env.RowCount == nil {
+		return 0, fmt.Errorf("no remote connection available to query row counts")
+	}
+	return env.RowCount(ctx, strings.Trim(table, `"`))
+}
@@ -0,0 +1,155 @@
+package lint
+
+import (
+	"context"
+	"testing"
+)
+
+func checkOne(t *testing.T, rule Rule, stmt string, rowCount func(context.Context, string) (int64, error)) []Finding {
+	t.Helper()
+	env := &Env{
+		File:      "20230101000000_test.sql",
+		Line:      1,
+		Statement: stripComments(stmt),
+		Threshold: defaultRowThreshold,
+		RowCount:  rowCount,
+	}
+	return rule.Check(context.Background(), env)
+}
+
+func TestNoDestructiveWithoutGuard(t *testing.T) {
+	rule := noDestructiveWithoutGuard{}
+
+	cases := []struct {
+		name      string
+		stmt      string
+		wantFound bool
+	}{
+		{"bare drop", "DROP TABLE foo;", true},
+		{"guarded drop", "DROP TABLE IF EXISTS foo;", false},
+		{"line comment before drop", "-- remove deprecated column\nALTER TABLE foo DROP COLUMN bar;", true},
+		{"block comment before drop", "/* deprecated */\nDROP TABLE foo;", true},
+		{"unrelated statement", "ALTER TABLE foo ADD COLUMN bar int;", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings := checkOne(t, rule, tc.stmt, nil)
+			if found := len(findings) > 0; found != tc.wantFound {
+				t.Errorf("Check(%q) found=%v, want %v", tc.stmt, found, tc.wantFound)
+			}
+		})
+	}
+}
+
+func TestNoNotNullWithoutDefault(t *testing.T) {
+	rule := noNotNullWithoutDefault{}
+	stmt := "ALTER TABLE foo ADD COLUMN bar int NOT NULL;"
+
+	t.Run("no remote connection warns instead of silently passing", func(t *testing.T) {
+		findings := checkOne(t, rule, stmt, nil)
+		if len(findings) != 1 || findings[0].Severity != SeverityWarning {
+			t.Fatalf("Check() = %+v, want a single warning-level finding", findings)
+		}
+	})
+
+	t.Run("empty table is not flagged", func(t *testing.T) {
+		findings := checkOne(t, rule, stmt, func(context.Context, string) (int64, error) { return 0, nil })
+		if len(findings) != 0 {
+			t.Fatalf("Check() = %+v, want no findings for an empty table", findings)
+		}
+	})
+
+	t.Run("non-empty table without default is flagged", func(t *testing.T) {
+		findings := checkOne(t, rule, stmt, func(context.Context, string) (int64, error) { return 10, nil })
+		if len(findings) != 1 || findings[0].Severity != SeverityError {
+			t.Fatalf("Check() = %+v, want a single error-level finding", findings)
+		}
+	})
+
+	t.Run("with a default is never flagged", func(t *testing.T) {
+		findings := checkOne(t, rule, "ALTER TABLE foo ADD COLUMN bar int NOT NULL DEFAULT 0;",
+			func(context.Context, string) (int64, error) { return 10, nil })
+		if len(findings) != 0 {
+			t.Fatalf("Check() = %+v, want no findings when a DEFAULT is present", findings)
+		}
+	})
+}
+
+func TestNoConcurrentIndex(t *testing.T) {
+	rule := noConcurrentIndex{}
+	stmt := "CREATE INDEX idx_foo ON foo (bar);"
+
+	t.Run("no remote connection warns instead of silently passing", func(t *testing.T) {
+		findings := checkOne(t, rule, stmt, nil)
+		if len(findings) != 1 || findings[0].Severity != SeverityWarning {
+			t.Fatalf("Check() = %+v, want a single warning-level finding", findings)
+		}
+	})
+
+	t.Run("below threshold is not flagged", func(t *testing.T) {
+		findings := checkOne(t, rule, stmt, func(context.Context, string) (int64, error) { return 1, nil })
+		if len(findings) != 0 {
+			t.Fatalf("Check() = %+v, want no findings below the row threshold", findings)
+		}
+	})
+
+	t.Run("above threshold is flagged", func(t *testing.T) {
+		findings := checkOne(t, rule, stmt, func(context.Context, string) (int64, error) { return defaultRowThreshold + 1, nil })
+		if len(findings) != 1 {
+			t.Fatalf("Check() = %+v, want a single finding above the row threshold", findings)
+		}
+	})
+
+	t.Run("CONCURRENTLY is never flagged", func(t *testing.T) {
+		findings := checkOne(t, rule, "CREATE INDEX CONCURRENTLY idx_foo ON foo (bar);",
+			func(context.Context, string) (int64, error) { return defaultRowThreshold + 1, nil })
+		if len(findings) != 0 {
+			t.Fatalf("Check() = %+v, want no findings when CONCURRENTLY is used", findings)
+		}
+	})
+}
+
+func TestIdentifierLengthLimit(t *testing.T) {
+	rule := identifierLengthLimit{}
+	long := "a_very_long_identifier_that_is_over_sixty_three_characters_long_indeed"
+
+	findings := checkOne(t, rule, "CREATE TABLE "+long+" (id int);", nil)
+	if len(findings) != 1 {
+		t.Fatalf("Check() = %+v, want a single finding for an over-long identifier", findings)
+	}
+
+	findings = checkOne(t, rule, "CREATE TABLE foo (id int);", nil)
+	if len(findings) != 0 {
+		t.Fatalf("Check() = %+v, want no findings for a short identifier", findings)
+	}
+}
+
+func TestNoInternalSchemaEdits(t *testing.T) {
+	rule := noInternalSchemaEdits{}
+
+	findings := checkOne(t, rule, "ALTER TABLE auth.users ADD COLUMN bar int;", nil)
+	if len(findings) != 1 {
+		t.Fatalf("Check() = %+v, want a single finding for editing an internal schema", findings)
+	}
+
+	findings = checkOne(t, rule, "ALTER TABLE public.users ADD COLUMN bar int;", nil)
+	if len(findings) != 0 {
+		t.Fatalf("Check() = %+v, want no findings for a public-schema edit", findings)
+	}
+}
+
+func TestSplitStatementsIgnoresSemicolonsInsideDollarQuotes(t *testing.T) {
+	sql := `CREATE FUNCTION foo() RETURNS void AS $$
+BEGIN
+  PERFORM 1;
+  PERFORM 2;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TABLE bar (id int);
+`
+	statements := splitStatements(sql)
+	if len(statements) != 2 {
+		t.Fatalf("splitStatements() returned %d statements, want 2: %+v", len(statements), statements)
+	}
+}
@@ -0,0 +1,233 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/jackc/pgx/v4"
+	"github.com/spf13/afero"
+	"github.com/supabase/cli/internal/db/dump"
+	"github.com/supabase/cli/internal/migration/up"
+	"github.com/supabase/cli/internal/utils"
+)
+
+// Run diffs the end-state schema produced by replaying supabase/migrations
+// at fromRef against the end-state schema at toRef, and reports any drift.
+// A non-empty diff means squashing, reordering, or editing migrations on one
+// ref produced a different database than the other -- exactly the kind of
+// mismatch AssertRemoteInSync assumes can never happen.
+func Run(ctx context.Context, fromRef, toRef, outputPath string, fsys afero.Fs) error {
+	if err := utils.AssertDockerIsRunning(ctx); err != nil {
+		return err
+	}
+	if err := utils.LoadConfigFS(fsys); err != nil {
+		return err
+	}
+
+	from, err := resolveRef(ctx, fromRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", fromRef, err)
+	}
+	to, err := resolveRef(ctx, toRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", toRef, err)
+	}
+
+	return utils.RunProgram(ctx, func(p utils.Program, ctx context.Context) error {
+		return run(p, ctx, from, to, outputPath, fsys)
+	})
+}
+
+func run(p utils.Program, ctx context.Context, fromRef, toRef, outputPath string, fsys afero.Fs) error {
+	p.Send(utils.StatusMsg(fmt.Sprintf("Dumping end-state schema for %s...", utils.Aqua(fromRef))))
+	fromSchema, err := dumpSchemaAtRef(ctx, fromRef, "shadow-db-from", fsys)
+	if err != nil {
+		return err
+	}
+
+	p.Send(utils.StatusMsg(fmt.Sprintf("Dumping end-state schema for %s...", utils.Aqua(toRef))))
+	toSchema, err := dumpSchemaAtRef(ctx, toRef, "shadow-db-to", fsys)
+	if err != nil {
+		return err
+	}
+
+	p.Send(utils.StatusMsg("Comparing schemas with migra..."))
+	diff, err := diffSchemas(ctx, fromSchema, toSchema)
+	if err != nil {
+		return err
+	}
+
+	if len(diff) == 0 {
+		fmt.Println("No schema drift found between " + utils.Aqua(fromRef) + " and " + utils.Aqua(toRef) + ".")
+		return nil
+	}
+
+	if len(outputPath) > 0 {
+		if err := afero.WriteFile(fsys, outputPath, []byte(diff), 0644); err != nil {
+			return err
+		}
+		fmt.Println("Schema drift detected, diff written to " + utils.Bold(outputPath) + ".")
+	} else {
+		fmt.Println(diff)
+	}
+	return errors.New("migrations at " + utils.Aqua(fromRef) + " and " + utils.Aqua(toRef) + " produce different end-states")
+}
+
+// dumpSchemaAtRef checks out supabase/migrations as of ref into a temp
+// directory, applies them against a disposable Pg15Image container named
+// containerName, and returns a schema-only pg_dump of the result. The dump
+// still contains the supabase_migrations schema; diffSchemas excludes it so
+// bookkeeping never shows up as drift.
+func dumpSchemaAtRef(ctx context.Context, ref, containerName string, fsys afero.Fs) (string, error) {
+	tempDir, err := os.MkdirTemp("", "migration-test-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tempDir)
+	tempFs := afero.NewBasePathFs(afero.NewOsFs(), tempDir)
+
+	if err := archiveMigrations(ctx, ref, tempDir); err != nil {
+		return "", err
+	}
+
+	host, cleanup, err := startShadowDatabase(ctx, containerName)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	conn, err := pgx.Connect(ctx, fmt.Sprintf("postgresql://postgres:postgres@%s/postgres", host))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close(context.Background())
+
+	if err := up.Run(ctx, conn, tempFs); err != nil {
+		return "", err
+	}
+
+	dumpPath := filepath.Join(tempDir, "schema.sql")
+	if err := dump.Run(ctx, dumpPath, "postgres", "postgres", "postgres", host, false, fsys); err != nil {
+		return "", err
+	}
+	out, err := afero.ReadFile(afero.NewOsFs(), dumpPath)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func resolveRef(ctx context.Context, ref string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", ref).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// archiveMigrations checks out utils.MigrationsDir as of ref into destDir.
+// A ref that simply predates any migrations (the pathspec matches nothing)
+// is a valid, empty baseline; any other git or tar failure is a hard error.
+func archiveMigrations(ctx context.Context, ref, destDir string) error {
+	archive := exec.CommandContext(ctx, "git", "archive", ref, "--", utils.MigrationsDir)
+	extract := exec.CommandContext(ctx, "tar", "-x", "-C", destDir)
+
+	var archiveStderr bytes.Buffer
+	archive.Stderr = &archiveStderr
+
+	pipe, err := archive.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	extract.Stdin = pipe
+
+	if err := extract.Start(); err != nil {
+		return err
+	}
+	if err := archive.Run(); err != nil {
+		// extract already consumed whatever archive wrote before exiting, so
+		// it must always be waited on too, regardless of how archive failed.
+		waitErr := extract.Wait()
+		if strings.Contains(archiveStderr.String(), "did not match any files") {
+			return waitErr
+		}
+		return fmt.Errorf("git archive %s failed: %w: %s", ref, err, archiveStderr.String())
+	}
+	return extract.Wait()
+}
+
+func startShadowDatabase(ctx context.Context, containerName string) (string, func(), error) {
+	resp, err := utils.Docker.ContainerCreate(
+		ctx,
+		&container.Config{
+			Image: utils.Pg15Image,
+			Env:   []string{"POSTGRES_PASSWORD=postgres"},
+		},
+		&container.HostConfig{PublishAllPorts: true, AutoRemove: true},
+		nil,
+		nil,
+		containerName,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := utils.Docker.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return "", nil, err
+	}
+	cleanup := func() {
+		_ = utils.Docker.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+	}
+
+	// Bare host:port, same shape dump.Run already expects from
+	// utils.GetSupabaseDbHost in internal/db/remote/commit.
+	host, err := utils.GetHostPort(ctx, resp.ID, "5432/tcp")
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return host, cleanup, nil
+}
+
+// diffSchemas feeds both schema dumps into the MigraImage container already
+// wired up for `supabase db diff` and returns the resulting SQL diff.
+func diffSchemas(ctx context.Context, fromSchema, toSchema string) (string, error) {
+	fromFile, err := os.CreateTemp("", "from-*.sql")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(fromFile.Name())
+	if _, err := fromFile.WriteString(fromSchema); err != nil {
+		return "", err
+	}
+	fromFile.Close()
+
+	toFile, err := os.CreateTemp("", "to-*.sql")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(toFile.Name())
+	if _, err := toFile.WriteString(toSchema); err != nil {
+		return "", err
+	}
+	toFile.Close()
+
+	out, err := exec.CommandContext(ctx, "docker", "run", "--rm",
+		"-v", fromFile.Name()+":/tmp/from.sql",
+		"-v", toFile.Name()+":/tmp/to.sql",
+		utils.MigraImage, "migra", "--unsafe", "--exclude-schema", "supabase_migrations",
+		"/tmp/from.sql", "/tmp/to.sql",
+	).Output()
+	// migra exits 2 when a diff is found; only bail out on unexpected failures.
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 2) {
+		return "", err
+	}
+	return string(out), nil
+}